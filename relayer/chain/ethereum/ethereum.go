@@ -0,0 +1,36 @@
+package ethereum
+
+import (
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultConfirmations is a confirmation depth for Config.Confirmations. It
+// is not applied automatically: a Config whose Confirmations is zero has
+// NewMMRRoot events processed in the chain tip block itself.
+const DefaultConfirmations = 20
+
+// Config holds the Ethereum-side contract addresses and reorg-safety
+// parameters BeefyListener needs to relay BEEFY commitments to Ethereum.
+type Config struct {
+	BeefyLightClient           string
+	BasicInboundChannel        string
+	IncentivizedInboundChannel string
+	// Confirmations is how many blocks behind the chain tip a header must be
+	// before NewMMRRoot events in it are processed.
+	Confirmations uint64
+}
+
+// Connection wraps a connection to an Ethereum node, used to query events
+// and call contracts needed to relay BEEFY commitments.
+type Connection struct {
+	client *ethclient.Client
+}
+
+// NewConnection returns a Connection wrapping client.
+func NewConnection(client *ethclient.Client) *Connection {
+	return &Connection{client: client}
+}
+
+func (co *Connection) GetClient() *ethclient.Client {
+	return co.client
+}
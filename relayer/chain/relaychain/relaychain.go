@@ -0,0 +1,118 @@
+package relaychain
+
+import (
+	gsrpc "github.com/snowfork/go-substrate-rpc-client/v2"
+	"github.com/snowfork/go-substrate-rpc-client/v2/types"
+)
+
+// ParaHead pairs a parachain's raw runtime head data with its ParaId,
+// mirroring the (ParaId, HeadData) entries the relay chain commits to in the
+// ParachainHeads field of a BEEFY MMR leaf.
+type ParaHead struct {
+	ParaID   uint32
+	HeadData types.Bytes
+	Header   types.Header
+}
+
+// Connection wraps a connection to a relay chain node, used to query MMR
+// leaves and parachain heads needed to relay BEEFY commitments to Ethereum.
+type Connection struct {
+	api *gsrpc.SubstrateAPI
+}
+
+func (co *Connection) GetAPI() *gsrpc.SubstrateAPI {
+	return co.api
+}
+
+// GetMMRLeafForBlock returns the MMR leaf and proof for blockNumber, as seen
+// at blockHash.
+func (co *Connection) GetMMRLeafForBlock(blockNumber uint64, blockHash types.Hash) types.GenerateMMRProofResponse {
+	proof, err := co.api.RPC.MMR.GenerateProof(blockNumber, blockHash)
+	if err != nil {
+		panic(err)
+	}
+	return proof
+}
+
+// GetAllParaheadsWithOwn returns the head of every parachain listed in the
+// relay chain's Paras::Parachains storage, read from Paras::Heads at
+// blockHash and paired with its ParaId,
+// along with the head belonging to ownParaID.
+func (co *Connection) GetAllParaheadsWithOwn(blockHash types.Hash, ownParaID uint32) ([]ParaHead, ParaHead) {
+	paraIDs, err := co.getActiveParaIDs(blockHash)
+	if err != nil {
+		panic(err)
+	}
+
+	var ownParaHead ParaHead
+	allParaHeads := make([]ParaHead, 0, len(paraIDs))
+
+	for _, paraID := range paraIDs {
+		headData, err := co.getHeadData(blockHash, paraID)
+		if err != nil {
+			panic(err)
+		}
+
+		var header types.Header
+		if err := types.DecodeFromBytes(headData, &header); err != nil {
+			panic(err)
+		}
+
+		paraHead := ParaHead{ParaID: paraID, HeadData: headData, Header: header}
+		allParaHeads = append(allParaHeads, paraHead)
+		if paraID == ownParaID {
+			ownParaHead = paraHead
+		}
+	}
+
+	return allParaHeads, ownParaHead
+}
+
+// getActiveParaIDs reads the `Paras::Parachains` storage value, the list of
+// every registered ParaId, as seen at blockHash.
+func (co *Connection) getActiveParaIDs(blockHash types.Hash) ([]uint32, error) {
+	meta, err := co.api.RPC.State.GetMetadataLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	storageKey, err := types.CreateStorageKey(meta, "Paras", "Parachains", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var paraIDs []uint32
+	_, err = co.api.RPC.State.GetStorage(storageKey, &paraIDs, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return paraIDs, nil
+}
+
+// getHeadData reads the `Paras::Heads` storage map for paraID, as seen at
+// blockHash.
+func (co *Connection) getHeadData(blockHash types.Hash, paraID uint32) (types.Bytes, error) {
+	meta, err := co.api.RPC.State.GetMetadataLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	encodedParaID, err := types.EncodeToBytes(types.U32(paraID))
+	if err != nil {
+		return nil, err
+	}
+
+	storageKey, err := types.CreateStorageKey(meta, "Paras", "Heads", encodedParaID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var headData types.Bytes
+	_, err = co.api.RPC.State.GetStorage(storageKey, &headData, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return headData, nil
+}
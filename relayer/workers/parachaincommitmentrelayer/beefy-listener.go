@@ -6,6 +6,7 @@ import (
 	"log"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -19,30 +20,55 @@ import (
 	"github.com/snowfork/polkadot-ethereum/relayer/chain/ethereum"
 	"github.com/snowfork/polkadot-ethereum/relayer/chain/parachain"
 	"github.com/snowfork/polkadot-ethereum/relayer/chain/relaychain"
+	"github.com/snowfork/polkadot-ethereum/relayer/contracts/basic"
 	"github.com/snowfork/polkadot-ethereum/relayer/contracts/beefylightclient"
+	"github.com/snowfork/polkadot-ethereum/relayer/contracts/incentivized"
 	chainTypes "github.com/snowfork/polkadot-ethereum/relayer/substrate"
 )
 
-//TODO - put in config
-const OUR_PARACHAIN_ID = 200
+// DefaultMaxLeavesPerCatchup bounds how many MMR leaves catchupParaHeads will
+// walk per new MMR root, so that a relayer which has been offline for a long
+// time doesn't stall processing of new commitments while working through
+// history.
+const DefaultMaxLeavesPerCatchup = 128
+
+// ParachainConfig describes one of the sibling parachains this relayer
+// forwards commitments for. A single relayer instance can run against
+// multiple parachains sharing the same relay chain and BEEFY light client,
+// each with its own connection and set of basic/incentivized channels.
+type ParachainConfig struct {
+	ParaID     uint32
+	Connection *parachain.Connection
+	Channels   []chainTypes.ChannelID
+}
 
 type MessagePackage struct {
+	paraID         uint32
 	channelID      chainTypes.ChannelID
 	commitmentHash types.H256
 	commitmentData types.StorageDataRaw
 	paraHead       types.Header
-	paraHeadProof  string
+	paraHeadProof  ParachainHeaderProof
 	mmrProof       types.GenerateMMRProofResponse
 }
 
 type BeefyListener struct {
-	ethereumConfig      *ethereum.Config
-	ethereumConn        *ethereum.Connection
-	beefyLightClient    *beefylightclient.Contract
-	relaychainConn      *relaychain.Connection
-	parachainConnection *parachain.Connection
-	messages            chan<- MessagePackage
-	log                 *logrus.Entry
+	ethereumConfig             *ethereum.Config
+	ethereumConn               *ethereum.Connection
+	beefyLightClient           *beefylightclient.Contract
+	basicInboundChannel        *basic.ContractInboundChannel
+	incentivizedInboundChannel *incentivized.ContractInboundChannel
+	relaychainConn             *relaychain.Connection
+	parachains                 []ParachainConfig
+	maxLeavesPerCatchup        uint64
+	checkpointStore            CheckpointStore
+	channelNonces              map[parachainChannelKey]uint64
+	forwardedCommitments       map[commitmentKey]bool
+	forwardedRoots             map[types.H256]bool
+	lastEthereumBlock          uint64
+	lastRelaychainBlock        uint64
+	messages                   chan<- MessagePackage
+	log                        *logrus.Entry
 }
 
 type NewMMRRootEvent struct {
@@ -50,20 +76,57 @@ type NewMMRRootEvent struct {
 	blockNumber uint64
 }
 
+// commitmentKey uniquely identifies a single commitment forwarded to a single
+// parachain's channel, using the commitment's own identity rather than how
+// many times a catch-up walk has encountered it. A counter that increments on
+// every encounter breaks down whenever the on-chain watermark a walk stops at
+// hasn't advanced since the last walk (e.g. Ethereum inclusion lagging
+// relay-chain BEEFY cadence): the same commitments get re-discovered and,
+// with a replay-order counter, re-forwarded. paraID is part of the key
+// because ChannelID only distinguishes basic from incentivized and is shared
+// by every configured parachain.
+type commitmentKey struct {
+	paraID         uint32
+	channelID      chainTypes.ChannelID
+	commitmentHash types.H256
+}
+
+// parachainChannelKey identifies a single parachain's channel, for tracking
+// how many commitments have been forwarded on it. ChannelID alone isn't
+// enough since the same basic/incentivized ChannelID is shared by every
+// configured parachain.
+type parachainChannelKey struct {
+	paraID    uint32
+	channelID chainTypes.ChannelID
+}
+
+// NewBeefyListener constructs a BeefyListener. maxLeavesPerCatchup bounds how
+// many MMR leaves catchupParaHeads will walk per new MMR root; passing 0
+// selects DefaultMaxLeavesPerCatchup.
 func NewBeefyListener(
 	ethereumConfig *ethereum.Config,
 	ethereumConn *ethereum.Connection,
 	relaychainConn *relaychain.Connection,
-	parachainConnection *parachain.Connection,
+	parachains []ParachainConfig,
+	maxLeavesPerCatchup uint64,
+	checkpointStore CheckpointStore,
 	messages chan<- MessagePackage,
 	log *logrus.Entry) *BeefyListener {
+	if maxLeavesPerCatchup == 0 {
+		maxLeavesPerCatchup = DefaultMaxLeavesPerCatchup
+	}
 	return &BeefyListener{
-		ethereumConfig:      ethereumConfig,
-		ethereumConn:        ethereumConn,
-		relaychainConn:      relaychainConn,
-		parachainConnection: parachainConnection,
-		messages:            messages,
-		log:                 log,
+		ethereumConfig:       ethereumConfig,
+		ethereumConn:         ethereumConn,
+		relaychainConn:       relaychainConn,
+		parachains:           parachains,
+		maxLeavesPerCatchup:  maxLeavesPerCatchup,
+		checkpointStore:      checkpointStore,
+		channelNonces:        make(map[parachainChannelKey]uint64),
+		forwardedCommitments: make(map[commitmentKey]bool),
+		forwardedRoots:       make(map[types.H256]bool),
+		messages:             messages,
+		log:                  log,
 	}
 }
 
@@ -76,15 +139,43 @@ func (li *BeefyListener) Start(ctx context.Context, eg *errgroup.Group) error {
 	}
 	li.beefyLightClient = beefyLightClientContract
 
+	// Set up inbound channel contracts, used to find out how far this
+	// relayer has already relayed commitments to on restart/catchup.
+	basicInboundChannelContract, err := basic.NewContractInboundChannel(common.HexToAddress(li.ethereumConfig.BasicInboundChannel), li.ethereumConn.GetClient())
+	if err != nil {
+		return err
+	}
+	li.basicInboundChannel = basicInboundChannelContract
+
+	incentivizedInboundChannelContract, err := incentivized.NewContractInboundChannel(common.HexToAddress(li.ethereumConfig.IncentivizedInboundChannel), li.ethereumConn.GetClient())
+	if err != nil {
+		return err
+	}
+	li.incentivizedInboundChannel = incentivizedInboundChannelContract
+
+	checkpoint, err := li.checkpointStore.Load()
+	if err != nil {
+		return err
+	}
+	for _, entry := range checkpoint.ChannelNonces {
+		li.channelNonces[parachainChannelKey{paraID: entry.ParaID, channelID: entry.ChannelID}] = entry.Nonce
+	}
+	for _, entry := range checkpoint.ForwardedCommitments {
+		li.forwardedCommitments[commitmentKey{paraID: entry.ParaID, channelID: entry.ChannelID, commitmentHash: entry.CommitmentHash}] = true
+	}
+	for _, root := range checkpoint.ForwardedMMRRoots {
+		li.forwardedRoots[root] = true
+	}
+	li.lastEthereumBlock = checkpoint.EthereumBlockOfLastMMRRoot
+	li.lastRelaychainBlock = checkpoint.RelaychainBlockOfLastLeaf
+
 	eg.Go(func() error {
 
-		blockNumber, hash, err := li.fetchLatestBlockAndHash()
-		if err != nil {
-			return nil
+		if err := li.catchupFromCheckpoint(ctx, checkpoint); err != nil {
+			li.log.WithError(err).Error("Failed to catch up from checkpoint")
+			return err
 		}
 
-		li.catchupMissedCommitments(ctx, blockNumber, hash)
-
 		err = li.subBeefyJustifications(ctx)
 		return err
 	})
@@ -92,6 +183,28 @@ func (li *BeefyListener) Start(ctx context.Context, eg *errgroup.Group) error {
 	return nil
 }
 
+// catchupFromCheckpoint resumes relaying from the last persisted checkpoint.
+// A fresh checkpoint (no prior runs) has EthereumBlockOfLastMMRRoot at its
+// zero value, which is exactly the Ethereum block to start scanning
+// NewMMRRoot events from, so there's no separate fresh-start path: this
+// re-scans queryBeefyLightClientEvents starting at the Ethereum block of the
+// last processed MMR root (0 on a fresh start), and relies on
+// lastProcessedParachainBlock/alreadyForwarded to skip anything already
+// relayed.
+func (li *BeefyListener) catchupFromCheckpoint(ctx context.Context, checkpoint Checkpoint) error {
+	li.log.WithFields(logrus.Fields{
+		"ethereumBlock":  checkpoint.EthereumBlockOfLastMMRRoot,
+		"relaychainLeaf": checkpoint.RelaychainBlockOfLastLeaf,
+	}).Info("Catching up from persisted checkpoint")
+
+	events, err := li.queryBeefyLightClientEvents(ctx, checkpoint.EthereumBlockOfLastMMRRoot, nil)
+	if err != nil {
+		return err
+	}
+
+	return li.processBeefyLightClientEvents(ctx, events)
+}
+
 func (li *BeefyListener) onDone(ctx context.Context) error {
 	li.log.Info("Shutting down listener...")
 	if li.messages != nil {
@@ -100,31 +213,149 @@ func (li *BeefyListener) onDone(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// subBeefyJustifications watches for new Ethereum heads and processes
+// NewMMRRoot events Confirmations blocks behind the chain tip, so a
+// short-lived reorg can't cause an event to be double-emitted or skipped. If
+// the node doesn't support header subscriptions it falls back to polling
+// HeaderByNumber.
 func (li *BeefyListener) subBeefyJustifications(ctx context.Context) error {
 	headers := make(chan *gethTypes.Header, 5)
 
-	li.ethereumConn.GetClient().SubscribeNewHead(ctx, headers)
+	sub, err := li.ethereumConn.GetClient().SubscribeNewHead(ctx, headers)
+	if err != nil {
+		li.log.WithError(err).Warn("Node does not support header subscriptions, falling back to polling")
+		return li.pollNewHeads(ctx)
+	}
+	defer sub.Unsubscribe()
+
+	buffer := newHeaderBuffer(li.ethereumConfig.Confirmations)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return li.onDone(ctx)
+		case err := <-sub.Err():
+			li.log.WithError(err).Warn("Header subscription dropped, falling back to polling")
+			return li.pollNewHeads(ctx)
 		case gethheader := <-headers:
-			// Query LightClientBridge contract's ContractNewMMRRoot events
-			blockNumber := gethheader.Number.Uint64()
-			var beefyLightClientEvents []*beefylightclient.ContractNewMMRRoot
-
-			contractEvents, err := li.queryBeefyLightClientEvents(ctx, blockNumber, &blockNumber)
-			if err != nil {
-				li.log.WithError(err).Error("Failure fetching event logs")
+			if err := li.handleNewHead(ctx, gethheader, buffer); err != nil {
 				return err
 			}
-			beefyLightClientEvents = append(beefyLightClientEvents, contractEvents...)
+		}
+	}
+}
+
+// handleNewHead buffers gethheader, detects a reorg against the previously
+// buffered header at the same height, and processes NewMMRRoot events for the
+// block Confirmations behind gethheader.
+func (li *BeefyListener) handleNewHead(ctx context.Context, gethheader *gethTypes.Header, buffer *headerBuffer) error {
+	if prior, ok := buffer.get(gethheader.Number.Uint64()); ok && prior.Hash() != gethheader.Hash() {
+		li.log.WithFields(logrus.Fields{
+			"blockNumber": gethheader.Number.Uint64(),
+			"priorHash":   prior.Hash().Hex(),
+			"newHash":     gethheader.Hash().Hex(),
+		}).Warn("Detected Ethereum reorg, re-scanning affected range")
+
+		if err := li.rescanAfterReorg(ctx, prior.Number.Uint64()); err != nil {
+			return err
+		}
+	}
+	buffer.add(gethheader)
+
+	if gethheader.Number.Uint64() <= li.ethereumConfig.Confirmations {
+		return nil
+	}
+	confirmedBlockNumber := gethheader.Number.Uint64() - li.ethereumConfig.Confirmations
+
+	return li.processBlockForNewMMRRoots(ctx, confirmedBlockNumber)
+}
+
+// processBlockForNewMMRRoots queries NewMMRRoot events at blockNumber,
+// de-duplicates them against li.forwardedRoots, and processes the rest.
+func (li *BeefyListener) processBlockForNewMMRRoots(ctx context.Context, blockNumber uint64) error {
+	contractEvents, err := li.queryBeefyLightClientEvents(ctx, blockNumber, &blockNumber)
+	if err != nil {
+		li.log.WithError(err).Error("Failure fetching event logs")
+		return err
+	}
+
+	newEvents, err := li.filterForwardedRoots(contractEvents)
+	if err != nil {
+		return err
+	}
+
+	if len(newEvents) > 0 {
+		li.log.Info(fmt.Sprintf("Found %d BeefyLightClient ContractNewMMRRoot events on block %d", len(newEvents), blockNumber))
+	}
+
+	return li.processBeefyLightClientEvents(ctx, newEvents)
+}
+
+// rescanAfterReorg re-queries NewMMRRoot events from fromBlockNumber, the
+// height at which a replaced header was detected, to the current chain tip,
+// so any event only visible on the new fork is picked up. Events whose roots
+// were already forwarded are skipped via filterForwardedRoots.
+func (li *BeefyListener) rescanAfterReorg(ctx context.Context, fromBlockNumber uint64) error {
+	head, err := li.ethereumConn.GetClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	toBlockNumber := head.Number.Uint64()
+	contractEvents, err := li.queryBeefyLightClientEvents(ctx, fromBlockNumber, &toBlockNumber)
+	if err != nil {
+		return err
+	}
+
+	newEvents, err := li.filterForwardedRoots(contractEvents)
+	if err != nil {
+		return err
+	}
 
-			if len(beefyLightClientEvents) > 0 {
-				li.log.Info(fmt.Sprintf("Found %d BeefyLightClient ContractNewMMRRoot events on block %d", len(beefyLightClientEvents), blockNumber))
+	return li.processBeefyLightClientEvents(ctx, newEvents)
+}
+
+// filterForwardedRoots drops events whose MmrRoot has already been forwarded,
+// according to li.forwardedRoots, and persists the rest as forwarded before
+// returning them, so a crash between persisting and actually relaying a
+// commitment loses at most the in-flight batch rather than re-forwarding
+// everything from the same root after every restart.
+func (li *BeefyListener) filterForwardedRoots(events []*beefylightclient.ContractNewMMRRoot) ([]*beefylightclient.ContractNewMMRRoot, error) {
+	var newEvents []*beefylightclient.ContractNewMMRRoot
+	for _, event := range events {
+		if li.forwardedRoots[event.MmrRoot] {
+			continue
+		}
+		if err := li.persistForwardedRoot(event.MmrRoot); err != nil {
+			return nil, err
+		}
+		newEvents = append(newEvents, event)
+	}
+	return newEvents, nil
+}
+
+// pollNewHeads is a fallback for RPC endpoints that don't support
+// SubscribeNewHead. It polls HeaderByNumber on a timer and otherwise follows
+// the same confirmations and reorg-detection logic as subBeefyJustifications.
+func (li *BeefyListener) pollNewHeads(ctx context.Context) error {
+	buffer := newHeaderBuffer(li.ethereumConfig.Confirmations)
+
+	ticker := time.NewTicker(pollNewHeadsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return li.onDone(ctx)
+		case <-ticker.C:
+			gethheader, err := li.ethereumConn.GetClient().HeaderByNumber(ctx, nil)
+			if err != nil {
+				li.log.WithError(err).Error("Failed to poll for new head")
+				continue
+			}
+			if err := li.handleNewHead(ctx, gethheader, buffer); err != nil {
+				return err
 			}
-			li.processBeefyLightClientEvents(ctx, beefyLightClientEvents)
 		}
 	}
 }
@@ -159,39 +390,118 @@ func (li *BeefyListener) processBeefyLightClientEvents(ctx context.Context, even
 		}
 		li.log.WithField("blockHash", blockHash.Hex()).Info("Got blockhash")
 
-		// TODO this just queries the latest MMR leaf in the latest MMR and our latest parahead from the relaychain.
-		// we should ideally be querying the latest and last few leaves in the latest MMR until we find
-		// the first parachain block that has not yet been fully processed on ethereum,
-		// and then package and relay all newer heads/commitments together with their corresponding leaf
-		mmrProof := li.relaychainConn.GetMMRLeafForBlock(uint64(relayChainBlockNumber-1), blockHash)
-		allParaHeads, ourParaHead := li.relaychainConn.GetAllParaheadsWithOwn(blockHash, OUR_PARACHAIN_ID)
-
-		ourParaHeadProof := createParachainHeaderProof(allParaHeads, ourParaHead)
-
-		messagePackets, err := li.extractCommitments(ourParaHead, mmrProof, ourParaHeadProof)
-		if err != nil {
-			li.log.WithError(err).Error("Failed to extract commitment and messages")
-		}
-		if len(messagePackets) == 0 {
-			li.log.Info("Parachain header has no commitment with messages, skipping...")
-			continue
-		}
-		for _, messagePacket := range messagePackets {
-			li.log.WithFields(logrus.Fields{
-				"channelID":        messagePacket.channelID,
-				"commitmentHash":   messagePacket.commitmentHash,
-				"commitmentData":   messagePacket.commitmentData,
-				"ourParaHeadProof": messagePacket.paraHeadProof,
-				"mmrProof":         messagePacket.mmrProof,
-			}).Info("Beefy Listener emitted new message packet")
-
-			li.messages <- messagePacket
+		// Walk every MMR leaf between our watermark and this one, rather than
+		// just the latest leaf, so parachain commitments produced between
+		// two BEEFY justifications aren't dropped. Every configured
+		// parachain is checked against the same ParachainHeads root, so the
+		// MMR leaves and parachain heads fetched for one parachain are
+		// cached and reused by the rest instead of being re-fetched per
+		// parachain.
+		leafCache := newRelayChainLeafCache()
+		for _, paraConfig := range li.parachains {
+			messagePackets, err := li.catchupParaHeads(ctx, paraConfig, blockHash, uint64(relayChainBlockNumber-1), leafCache)
+			if err != nil {
+				li.log.WithError(err).WithField("paraId", paraConfig.ParaID).Error("Failed to extract commitment and messages")
+				continue
+			}
+			if len(messagePackets) == 0 {
+				li.log.WithField("paraId", paraConfig.ParaID).Info("Parachain header has no commitment with messages, skipping...")
+				continue
+			}
+			for _, messagePacket := range messagePackets {
+				if li.alreadyForwarded(messagePacket.paraID, messagePacket.channelID, messagePacket.commitmentHash) {
+					continue
+				}
+
+				li.log.WithFields(logrus.Fields{
+					"paraId":           messagePacket.paraID,
+					"channelID":        messagePacket.channelID,
+					"commitmentHash":   messagePacket.commitmentHash,
+					"commitmentData":   messagePacket.commitmentData,
+					"ourParaHeadProof": messagePacket.paraHeadProof,
+					"mmrProof":         messagePacket.mmrProof,
+				}).Info("Beefy Listener emitted new message packet")
+
+				li.messages <- messagePacket
+
+				if err := li.recordCheckpoint(event.Raw.BlockNumber, uint64(relayChainBlockNumber-1), messagePacket.paraID, messagePacket.channelID, messagePacket.commitmentHash); err != nil {
+					li.log.WithError(err).Error("Failed to persist checkpoint")
+					return err
+				}
+			}
 		}
 
 	}
 	return nil
 }
 
+// alreadyForwarded reports whether commitmentHash has already been relayed on
+// paraID's channelID, keyed on the commitment's own identity rather than how
+// many times a catch-up walk has re-discovered it, so a re-walk of parachain
+// blocks whose on-chain watermark hasn't advanced yet doesn't re-forward
+// them.
+func (li *BeefyListener) alreadyForwarded(paraID uint32, channelID chainTypes.ChannelID, commitmentHash types.H256) bool {
+	return li.forwardedCommitments[commitmentKey{paraID: paraID, channelID: channelID, commitmentHash: commitmentHash}]
+}
+
+// recordCheckpoint persists a Checkpoint reflecting that the commitment
+// identified by commitmentHash has been relayed on paraID's channelID, so a
+// restart resumes from here instead of re-scanning from genesis.
+func (li *BeefyListener) recordCheckpoint(ethereumBlockOfLastMMRRoot, relaychainBlockOfLastLeaf uint64, paraID uint32, channelID chainTypes.ChannelID, commitmentHash types.H256) error {
+	li.channelNonces[parachainChannelKey{paraID: paraID, channelID: channelID}]++
+	li.forwardedCommitments[commitmentKey{paraID: paraID, channelID: channelID, commitmentHash: commitmentHash}] = true
+	return li.saveCheckpoint(ethereumBlockOfLastMMRRoot, relaychainBlockOfLastLeaf)
+}
+
+// persistForwardedRoot records that root has had its NewMMRRoot event
+// processed, persisting immediately rather than waiting for a commitment to
+// be relayed, so the persisted set also covers MMR roots that produced no
+// commitments at all. After a restart, processBlockForNewMMRRoots and
+// rescanAfterReorg skip such roots; catchupFromCheckpoint does not consult
+// forwardedRoots.
+func (li *BeefyListener) persistForwardedRoot(root types.H256) error {
+	li.forwardedRoots[root] = true
+	return li.saveCheckpoint(li.lastEthereumBlock, li.lastRelaychainBlock)
+}
+
+// saveCheckpoint persists a Checkpoint built from BeefyListener's current
+// in-memory state, updating lastEthereumBlock/lastRelaychainBlock to match.
+func (li *BeefyListener) saveCheckpoint(ethereumBlockOfLastMMRRoot, relaychainBlockOfLastLeaf uint64) error {
+	li.lastEthereumBlock = ethereumBlockOfLastMMRRoot
+	li.lastRelaychainBlock = relaychainBlockOfLastLeaf
+
+	channelNonces := make([]ChannelNonceEntry, 0, len(li.channelNonces))
+	for key, nonce := range li.channelNonces {
+		channelNonces = append(channelNonces, ChannelNonceEntry{
+			ParaID:    key.paraID,
+			ChannelID: key.channelID,
+			Nonce:     nonce,
+		})
+	}
+
+	forwardedCommitments := make([]ForwardedCommitmentEntry, 0, len(li.forwardedCommitments))
+	for key := range li.forwardedCommitments {
+		forwardedCommitments = append(forwardedCommitments, ForwardedCommitmentEntry{
+			ParaID:         key.paraID,
+			ChannelID:      key.channelID,
+			CommitmentHash: key.commitmentHash,
+		})
+	}
+
+	forwardedRoots := make([]types.H256, 0, len(li.forwardedRoots))
+	for root := range li.forwardedRoots {
+		forwardedRoots = append(forwardedRoots, root)
+	}
+
+	return li.checkpointStore.Save(Checkpoint{
+		EthereumBlockOfLastMMRRoot: ethereumBlockOfLastMMRRoot,
+		RelaychainBlockOfLastLeaf:  relaychainBlockOfLastLeaf,
+		ChannelNonces:              channelNonces,
+		ForwardedCommitments:       forwardedCommitments,
+		ForwardedMMRRoots:          forwardedRoots,
+	})
+}
+
 // queryBeefyLightClientEvents queries ContractNewMMRRoot events from the BeefyLightClient contract
 func (li *BeefyListener) queryBeefyLightClientEvents(ctx context.Context, start uint64,
 	end *uint64) ([]*beefylightclient.ContractNewMMRRoot, error) {
@@ -219,17 +529,14 @@ func (li *BeefyListener) queryBeefyLightClientEvents(ctx context.Context, start
 	return events, nil
 }
 
-func createParachainHeaderProof(allParaHeads []types.Header, ourParaHead types.Header) string {
-	//TODO: implement
-	return ""
-}
-
 func (li *BeefyListener) extractCommitments(
+	paraConfig ParachainConfig,
 	paraHeader types.Header,
 	mmrProof types.GenerateMMRProofResponse,
-	ourParaHeadProof string) ([]MessagePackage, error) {
+	ourParaHeadProof ParachainHeaderProof) ([]MessagePackage, error) {
 
 	li.log.WithFields(logrus.Fields{
+		"paraId":      paraConfig.ParaID,
 		"blockNumber": paraHeader.Number,
 	}).Debug("Extracting commitment from parachain header")
 
@@ -241,16 +548,18 @@ func (li *BeefyListener) extractCommitments(
 	var messagePackages []MessagePackage
 	for _, auxDigestItem := range auxDigestItems {
 		li.log.WithFields(logrus.Fields{
+			"paraId":         paraConfig.ParaID,
 			"block":          paraHeader.Number,
 			"channelID":      auxDigestItem.AsCommitment.ChannelID,
 			"commitmentHash": auxDigestItem.AsCommitment.Hash.Hex(),
 		}).Debug("Found commitment hash in header digest")
 		commitmentHash := auxDigestItem.AsCommitment.Hash
-		commitmentData, err := li.getDataForDigestItem(&auxDigestItem)
+		commitmentData, err := li.getDataForDigestItem(paraConfig.Connection, &auxDigestItem)
 		if err != nil {
 			return nil, err
 		}
 		messagePackage := MessagePackage{
+			paraConfig.ParaID,
 			auxDigestItem.AsCommitment.ChannelID,
 			commitmentHash,
 			commitmentData,
@@ -279,13 +588,13 @@ func (li *BeefyListener) getAuxiliaryDigestItems(digest types.Digest) ([]chainTy
 	return auxDigestItems, nil
 }
 
-func (li *BeefyListener) getDataForDigestItem(digestItem *chainTypes.AuxiliaryDigestItem) (types.StorageDataRaw, error) {
+func (li *BeefyListener) getDataForDigestItem(parachainConnection *parachain.Connection, digestItem *chainTypes.AuxiliaryDigestItem) (types.StorageDataRaw, error) {
 	storageKey, err := parachain.MakeStorageKey(digestItem.AsCommitment.ChannelID, digestItem.AsCommitment.Hash)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := li.parachainConnection.GetAPI().RPC.Offchain.LocalStorageGet(rpcOffchain.Persistent, storageKey)
+	data, err := parachainConnection.GetAPI().RPC.Offchain.LocalStorageGet(rpcOffchain.Persistent, storageKey)
 	if err != nil {
 		li.log.WithError(err).Error("Failed to read commitment from offchain storage")
 		return nil, err
@@ -0,0 +1,150 @@
+package parachaincommitmentrelayer
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/snowfork/go-substrate-rpc-client/v2/types"
+
+	"github.com/snowfork/polkadot-ethereum/relayer/chain/relaychain"
+)
+
+// relayChainLeafCache caches the per-leaf data catchupParaHeads reads from
+// the relay chain, keyed by relay chain block number. It is shared across
+// every configured parachain's catch-up walk against the same MMR root in
+// processBeefyLightClientEvents, since all parachains walk the same range of
+// leaves and every leaf commits to every parachain's head at once - without
+// sharing it, each additional parachain would re-fetch MMR leaves and
+// parachain heads that an earlier parachain's walk already fetched.
+type relayChainLeafCache struct {
+	mmrLeaves    map[uint64]types.GenerateMMRProofResponse
+	allParaHeads map[uint64][]relaychain.ParaHead
+}
+
+// newRelayChainLeafCache returns an empty relayChainLeafCache ready to be
+// shared across catchupParaHeads calls for a single MMR root.
+func newRelayChainLeafCache() *relayChainLeafCache {
+	return &relayChainLeafCache{
+		mmrLeaves:    make(map[uint64]types.GenerateMMRProofResponse),
+		allParaHeads: make(map[uint64][]relaychain.ParaHead),
+	}
+}
+
+// catchupParaHeads walks MMR leaves backwards from latestRelayChainBlockNumber
+// until it reaches a leaf whose parahead for paraConfig is at or below the
+// last parachain block the inbound channels on Ethereum have processed,
+// so that parachain blocks with commitments produced between two BEEFY
+// justifications aren't dropped. It emits one MessagePackage per commitment
+// found, oldest first, and stops early once maxLeavesPerCatchup leaves have
+// been walked to bound work done per MMR root. cache is shared across every
+// parachain's walk against the same blockHash, so MMR leaves and parachain
+// heads fetched for one parachain are reused by the rest.
+func (li *BeefyListener) catchupParaHeads(ctx context.Context, paraConfig ParachainConfig, blockHash types.Hash, latestRelayChainBlockNumber uint64, cache *relayChainLeafCache) ([]MessagePackage, error) {
+	watermark, err := li.lastProcessedParachainBlock(ctx, paraConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var messagePackets []MessagePackage
+	var leavesWalked uint64
+	for blockNumber := latestRelayChainBlockNumber; leavesWalked < li.maxLeavesPerCatchup; blockNumber-- {
+		mmrProof, ok := cache.mmrLeaves[blockNumber]
+		if !ok {
+			mmrProof = li.relaychainConn.GetMMRLeafForBlock(blockNumber, blockHash)
+			cache.mmrLeaves[blockNumber] = mmrProof
+		}
+
+		all, ok := cache.allParaHeads[blockNumber]
+		if !ok {
+			// The paraheads committed inside leaf blockNumber are whatever was
+			// canonical at that relay-chain block, not at the current tip, so
+			// they must be queried at blockNumber's own hash.
+			leafBlockHash, err := li.relaychainConn.GetAPI().RPC.Chain.GetBlockHash(blockNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			all, _ = li.relaychainConn.GetAllParaheadsWithOwn(leafBlockHash, paraConfig.ParaID)
+			cache.allParaHeads[blockNumber] = all
+		}
+
+		ourParaHead, ok := findParaHead(all, paraConfig.ParaID)
+		if !ok {
+			break
+		}
+
+		// lastProcessedParachainBlock reports a watermark in parachain block
+		// numbers, not relay-chain ones, so it must be compared against the
+		// parachain block number of the head committed in this leaf, not
+		// against blockNumber itself.
+		if uint64(ourParaHead.Header.Number) <= watermark {
+			break
+		}
+
+		ourParaHeadProof, err := createParachainHeaderProof(all, ourParaHead)
+		if err != nil {
+			return nil, err
+		}
+
+		packets, err := li.extractCommitments(paraConfig, ourParaHead.Header, mmrProof, ourParaHeadProof)
+		if err != nil {
+			return nil, err
+		}
+
+		messagePackets = append(messagePackets, packets...)
+		leavesWalked++
+
+		if blockNumber == 0 {
+			break
+		}
+	}
+
+	// Leaves were walked newest-to-oldest, but commitments must be relayed in
+	// the order they were produced.
+	reverseMessagePackets(messagePackets)
+
+	return messagePackets, nil
+}
+
+// findParaHead returns the ParaHead belonging to paraID out of all, the set
+// of every parachain head committed to by a single MMR leaf.
+func findParaHead(all []relaychain.ParaHead, paraID uint32) (relaychain.ParaHead, bool) {
+	for _, head := range all {
+		if head.ParaID == paraID {
+			return head, true
+		}
+	}
+	return relaychain.ParaHead{}, false
+}
+
+func reverseMessagePackets(packets []MessagePackage) {
+	for i, j := 0, len(packets)-1; i < j; i, j = i+1, j-1 {
+		packets[i], packets[j] = packets[j], packets[i]
+	}
+}
+
+// lastProcessedParachainBlock returns the parachain block number up to which
+// both the basic and incentivized inbound channels have processed
+// commitments (the older of the two channels' latest processed blocks), i.e.
+// the watermark catchupParaHeads stops walking backwards at for paraConfig.
+func (li *BeefyListener) lastProcessedParachainBlock(ctx context.Context, paraConfig ParachainConfig) (uint64, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	basicBlock, err := li.basicInboundChannel.LatestProcessedBlock(callOpts)
+	if err != nil {
+		return 0, err
+	}
+
+	incentivizedBlock, err := li.incentivizedInboundChannel.LatestProcessedBlock(callOpts)
+	if err != nil {
+		return 0, err
+	}
+
+	// Take the older of the two channels' watermarks, since catchupParaHeads
+	// must replay every parachain block that either channel hasn't
+	// processed yet.
+	if basicBlock.Uint64() < incentivizedBlock.Uint64() {
+		return basicBlock.Uint64(), nil
+	}
+	return incentivizedBlock.Uint64(), nil
+}
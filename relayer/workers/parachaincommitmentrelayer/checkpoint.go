@@ -0,0 +1,61 @@
+package parachaincommitmentrelayer
+
+import (
+	"github.com/snowfork/go-substrate-rpc-client/v2/types"
+
+	chainTypes "github.com/snowfork/polkadot-ethereum/relayer/substrate"
+)
+
+// Checkpoint records how far BeefyListener has relayed parachain
+// commitments, so a restart can resume without re-scanning Ethereum or the
+// relay chain from genesis.
+type Checkpoint struct {
+	EthereumBlockOfLastMMRRoot uint64
+	RelaychainBlockOfLastLeaf  uint64
+	// ChannelNonces counts commitments forwarded per parachain channel, keyed
+	// by (parachain, channel) rather than just channel, since ChannelID only
+	// distinguishes basic from incentivized and is shared by every configured
+	// parachain. Stored as a slice since the composite key isn't usable as a
+	// JSON object key.
+	ChannelNonces []ChannelNonceEntry
+	// ForwardedCommitments is the set of commitments already relayed, keyed by
+	// the (parachain, channel, commitment hash) identity of the commitment
+	// itself rather than the order catch-up walks encountered it in, so a
+	// re-walk of parachain blocks whose on-chain watermark hasn't advanced yet
+	// doesn't re-forward them. Stored as a slice since types.H256 isn't usable
+	// as a JSON object key.
+	ForwardedCommitments []ForwardedCommitmentEntry
+	// ForwardedMMRRoots is the set of BeefyLightClient NewMMRRoot event roots
+	// already processed, so a restart doesn't re-forward commitments from an
+	// MMR root it has already seen. Stored as a slice since types.H256 isn't
+	// usable as a JSON object key.
+	ForwardedMMRRoots []types.H256
+}
+
+// ChannelNonceEntry is the persisted count of commitments forwarded on one
+// parachain's channel.
+type ChannelNonceEntry struct {
+	ParaID    uint32
+	ChannelID chainTypes.ChannelID
+	Nonce     uint64
+}
+
+// ForwardedCommitmentEntry is the persisted form of one commitment already
+// relayed to a parachain's channel.
+type ForwardedCommitmentEntry struct {
+	ParaID         uint32
+	ChannelID      chainTypes.ChannelID
+	CommitmentHash types.H256
+}
+
+// CheckpointStore persists a Checkpoint so BeefyListener can resume after a
+// restart without re-scanning Ethereum or the relay chain from genesis.
+type CheckpointStore interface {
+	// Load returns the last persisted checkpoint, or the zero Checkpoint if
+	// none has been saved yet.
+	Load() (Checkpoint, error)
+	// Save persists checkpoint, replacing whatever was previously stored.
+	// Implementations must make the replace atomic, so a crash mid-write
+	// can't leave behind a corrupt or partially-updated checkpoint.
+	Save(checkpoint Checkpoint) error
+}
@@ -0,0 +1,202 @@
+package parachaincommitmentrelayer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowfork/go-substrate-rpc-client/v2/types"
+
+	chainTypes "github.com/snowfork/polkadot-ethereum/relayer/substrate"
+)
+
+// newResumeTestListener builds a BeefyListener with just enough state to
+// exercise alreadyForwarded and recordCheckpoint, the parts of the listener
+// involved in catch-up de-duplication after a restart.
+func newResumeTestListener(store CheckpointStore) *BeefyListener {
+	return &BeefyListener{
+		checkpointStore:      store,
+		channelNonces:        make(map[parachainChannelKey]uint64),
+		forwardedCommitments: make(map[commitmentKey]bool),
+		forwardedRoots:       make(map[types.H256]bool),
+	}
+}
+
+// loadInto restores a checkpoint from store into li, mirroring the subset of
+// Start's checkpoint-loading logic that alreadyForwarded/recordCheckpoint
+// depend on.
+func loadInto(t *testing.T, li *BeefyListener, store CheckpointStore) {
+	t.Helper()
+
+	checkpoint, err := store.Load()
+	if err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+	for _, entry := range checkpoint.ChannelNonces {
+		li.channelNonces[parachainChannelKey{paraID: entry.ParaID, channelID: entry.ChannelID}] = entry.Nonce
+	}
+	for _, entry := range checkpoint.ForwardedCommitments {
+		li.forwardedCommitments[commitmentKey{paraID: entry.ParaID, channelID: entry.ChannelID, commitmentHash: entry.CommitmentHash}] = true
+	}
+	for _, root := range checkpoint.ForwardedMMRRoots {
+		li.forwardedRoots[root] = true
+	}
+}
+
+// commitmentHashForTest derives a distinct, deterministic commitment hash for
+// index i, standing in for the real commitment hash a parachain header digest
+// would carry.
+func commitmentHashForTest(i int) types.H256 {
+	return types.NewH256([]byte{byte(i), 'c', 'o', 'm', 'm', 'i', 't', 'm', 'e', 'n', 't', '-', 'h', 'a', 's', 'h'})
+}
+
+// TestResumeAfterMidBatchCrashSkipsAlreadyForwardedCommitments simulates a
+// listener crashing partway through forwarding a batch of commitments on a
+// single channel, then restarting and re-walking the same batch from the
+// checkpointed MMR root. It asserts that every commitment already forwarded
+// before the crash is skipped on resume, and every commitment not yet
+// forwarded is still forwarded exactly once, so no commitment is dropped or
+// duplicated across the restart.
+func TestResumeAfterMidBatchCrashSkipsAlreadyForwardedCommitments(t *testing.T) {
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	const paraID = 200
+	channelID := chainTypes.ChannelID{IsBasic: true}
+
+	const batchSize = 5
+	const forwardedBeforeCrash = 3
+
+	// First run: forward the first forwardedBeforeCrash commitments of the
+	// batch, persisting a checkpoint after each one, then "crash" by
+	// discarding the in-memory listener without forwarding the rest.
+	first := newResumeTestListener(store)
+	var forwardedFirstRun int
+	for i := 0; i < forwardedBeforeCrash; i++ {
+		hash := commitmentHashForTest(i)
+		if first.alreadyForwarded(paraID, channelID, hash) {
+			t.Fatalf("commitment %d: unexpectedly considered already forwarded on a fresh listener", i)
+		}
+		forwardedFirstRun++
+		if err := first.recordCheckpoint(uint64(100+i), uint64(200+i), paraID, channelID, hash); err != nil {
+			t.Fatalf("failed to persist checkpoint: %v", err)
+		}
+	}
+	if forwardedFirstRun != forwardedBeforeCrash {
+		t.Fatalf("expected %d commitments forwarded before crash, got %d", forwardedBeforeCrash, forwardedFirstRun)
+	}
+
+	// Second run: a fresh listener loads the persisted checkpoint and
+	// re-walks the *entire* batch from the start, as catchupFromCheckpoint
+	// does after a restart.
+	second := newResumeTestListener(store)
+	loadInto(t, second, store)
+
+	var forwardedSecondRun, skippedSecondRun int
+	for i := 0; i < batchSize; i++ {
+		hash := commitmentHashForTest(i)
+		if second.alreadyForwarded(paraID, channelID, hash) {
+			skippedSecondRun++
+			continue
+		}
+		forwardedSecondRun++
+		if err := second.recordCheckpoint(uint64(100+i), uint64(200+i), paraID, channelID, hash); err != nil {
+			t.Fatalf("failed to persist checkpoint: %v", err)
+		}
+	}
+
+	if skippedSecondRun != forwardedBeforeCrash {
+		t.Fatalf("expected %d commitments skipped as already forwarded, got %d", forwardedBeforeCrash, skippedSecondRun)
+	}
+	if forwardedSecondRun != batchSize-forwardedBeforeCrash {
+		t.Fatalf("expected %d commitments forwarded on resume, got %d", batchSize-forwardedBeforeCrash, forwardedSecondRun)
+	}
+
+	totalForwarded := forwardedFirstRun + forwardedSecondRun
+	if totalForwarded != batchSize {
+		t.Fatalf("expected every commitment in the batch forwarded exactly once across both runs, got %d forwarded out of %d", totalForwarded, batchSize)
+	}
+}
+
+// TestAlreadyForwardedSurvivesRepeatedWalksWithoutRestart simulates the
+// steady-state case a replay-order counter would get wrong: no restart at
+// all, but catchupParaHeads re-walking and re-discovering the same batch of
+// commitments on consecutive MMR roots because the on-chain watermark for the
+// channel hasn't advanced yet (Ethereum inclusion lagging relay-chain BEEFY
+// cadence). It asserts every commitment is still forwarded exactly once no
+// matter how many times the same batch is re-walked.
+func TestAlreadyForwardedSurvivesRepeatedWalksWithoutRestart(t *testing.T) {
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	const paraID = 200
+	channelID := chainTypes.ChannelID{IsBasic: true}
+	li := newResumeTestListener(store)
+
+	const batchSize = 4
+	const walks = 5
+
+	forwardedCount := make(map[int]int)
+	for walk := 0; walk < walks; walk++ {
+		for i := 0; i < batchSize; i++ {
+			hash := commitmentHashForTest(i)
+			if li.alreadyForwarded(paraID, channelID, hash) {
+				continue
+			}
+			forwardedCount[i]++
+			if err := li.recordCheckpoint(uint64(100+i), uint64(200+i), paraID, channelID, hash); err != nil {
+				t.Fatalf("failed to persist checkpoint: %v", err)
+			}
+		}
+	}
+
+	for i := 0; i < batchSize; i++ {
+		if forwardedCount[i] != 1 {
+			t.Fatalf("commitment %d: expected to be forwarded exactly once across %d repeated walks, got %d", i, walks, forwardedCount[i])
+		}
+	}
+}
+
+// TestAlreadyForwardedDoesNotCollapseAcrossParachains simulates two sibling
+// parachains sharing the same ChannelID enum (basic/incentivized), and
+// asserts that forwarding a commitment on one parachain's channel doesn't
+// cause an identically-shaped commitment hash on another parachain's same
+// channel to be skipped, since ChannelID alone doesn't distinguish them.
+func TestAlreadyForwardedDoesNotCollapseAcrossParachains(t *testing.T) {
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	channelID := chainTypes.ChannelID{IsBasic: true}
+	li := newResumeTestListener(store)
+
+	const paraA, paraB = 200, 300
+	hash := commitmentHashForTest(0)
+
+	if li.alreadyForwarded(paraA, channelID, hash) {
+		t.Fatal("commitment unexpectedly considered already forwarded on a fresh listener")
+	}
+	if err := li.recordCheckpoint(100, 200, paraA, channelID, hash); err != nil {
+		t.Fatalf("failed to persist checkpoint: %v", err)
+	}
+
+	if !li.alreadyForwarded(paraA, channelID, hash) {
+		t.Fatal("expected commitment to be recognised as forwarded on paraA")
+	}
+	if li.alreadyForwarded(paraB, channelID, hash) {
+		t.Fatal("commitment forwarded on paraA's channel must not be treated as forwarded on paraB's channel")
+	}
+}
+
+// TestForwardedMMRRootPersistsAcrossRestartEvenWithoutCommitments simulates a
+// crash and restart after an MMR root has been marked forwarded but before
+// any commitment was found in it, the case persistForwardedRoot exists to
+// cover, and asserts the root is still recognised as forwarded after restart.
+func TestForwardedMMRRootPersistsAcrossRestartEvenWithoutCommitments(t *testing.T) {
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	root := types.NewH256([]byte("some-mmr-root-hash-3234567890ab"))
+
+	first := newResumeTestListener(store)
+	if err := first.persistForwardedRoot(root); err != nil {
+		t.Fatalf("failed to persist forwarded root: %v", err)
+	}
+
+	second := newResumeTestListener(store)
+	loadInto(t, second, store)
+
+	if !second.forwardedRoots[root] {
+		t.Fatal("expected root to still be marked forwarded after restart")
+	}
+}
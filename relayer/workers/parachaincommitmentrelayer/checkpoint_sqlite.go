@@ -0,0 +1,122 @@
+package parachaincommitmentrelayer
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteCheckpointRowID is the id of the single row this store keeps its
+// checkpoint in; there is only ever one checkpoint per relayer instance.
+const sqliteCheckpointRowID = 1
+
+// SQLiteCheckpointStore persists a Checkpoint to a SQLite database, writing
+// it within a transaction so a crash mid-write can never leave behind a
+// corrupt or partially-updated checkpoint.
+type SQLiteCheckpointStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteCheckpointStore opens (creating if necessary) a SQLite database
+// at path and ensures its checkpoint table exists.
+func NewSQLiteCheckpointStore(path string) (*SQLiteCheckpointStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS checkpoint (
+			id                           INTEGER PRIMARY KEY,
+			ethereum_block               INTEGER NOT NULL,
+			relaychain_block             INTEGER NOT NULL,
+			channel_nonces_json          TEXT NOT NULL,
+			forwarded_commitments_json   TEXT NOT NULL DEFAULT '[]',
+			forwarded_mmr_roots_json     TEXT NOT NULL DEFAULT '[]'
+		)
+	`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteCheckpointStore{db: db}, nil
+}
+
+func (s *SQLiteCheckpointStore) Load() (Checkpoint, error) {
+	var ethereumBlock, relaychainBlock uint64
+	var channelNoncesJSON, forwardedCommitmentsJSON, forwardedMMRRootsJSON string
+
+	row := s.db.QueryRow(
+		"SELECT ethereum_block, relaychain_block, channel_nonces_json, forwarded_commitments_json, forwarded_mmr_roots_json FROM checkpoint WHERE id = ?",
+		sqliteCheckpointRowID,
+	)
+	err := row.Scan(&ethereumBlock, &relaychainBlock, &channelNoncesJSON, &forwardedCommitmentsJSON, &forwardedMMRRootsJSON)
+	if err == sql.ErrNoRows {
+		return Checkpoint{}, nil
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	checkpoint := Checkpoint{
+		EthereumBlockOfLastMMRRoot: ethereumBlock,
+		RelaychainBlockOfLastLeaf:  relaychainBlock,
+	}
+	if err := json.Unmarshal([]byte(channelNoncesJSON), &checkpoint.ChannelNonces); err != nil {
+		return Checkpoint{}, err
+	}
+	if err := json.Unmarshal([]byte(forwardedCommitmentsJSON), &checkpoint.ForwardedCommitments); err != nil {
+		return Checkpoint{}, err
+	}
+	if err := json.Unmarshal([]byte(forwardedMMRRootsJSON), &checkpoint.ForwardedMMRRoots); err != nil {
+		return Checkpoint{}, err
+	}
+
+	return checkpoint, nil
+}
+
+func (s *SQLiteCheckpointStore) Save(checkpoint Checkpoint) error {
+	channelNoncesJSON, err := json.Marshal(checkpoint.ChannelNonces)
+	if err != nil {
+		return err
+	}
+
+	forwardedCommitmentsJSON, err := json.Marshal(checkpoint.ForwardedCommitments)
+	if err != nil {
+		return err
+	}
+
+	forwardedMMRRootsJSON, err := json.Marshal(checkpoint.ForwardedMMRRoots)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO checkpoint (id, ethereum_block, relaychain_block, channel_nonces_json, forwarded_commitments_json, forwarded_mmr_roots_json)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			ethereum_block = excluded.ethereum_block,
+			relaychain_block = excluded.relaychain_block,
+			channel_nonces_json = excluded.channel_nonces_json,
+			forwarded_commitments_json = excluded.forwarded_commitments_json,
+			forwarded_mmr_roots_json = excluded.forwarded_mmr_roots_json
+	`, sqliteCheckpointRowID, checkpoint.EthereumBlockOfLastMMRRoot, checkpoint.RelaychainBlockOfLastLeaf, string(channelNoncesJSON), string(forwardedCommitmentsJSON), string(forwardedMMRRootsJSON))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Close releases the underlying database handle.
+func (s *SQLiteCheckpointStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,49 @@
+package parachaincommitmentrelayer
+
+import (
+	"time"
+
+	gethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// pollNewHeadsInterval is how often pollNewHeads checks for a new Ethereum
+// head when the node doesn't support SubscribeNewHead.
+const pollNewHeadsInterval = 15 * time.Second
+
+// headerBuffer keeps the most recently seen Ethereum header at each block
+// height over a sliding window of twice confirmations blocks, so a newly arrived
+// header can be compared against what was previously buffered at the same
+// height to detect a reorg.
+type headerBuffer struct {
+	confirmations uint64
+	headers       map[uint64]*gethTypes.Header
+}
+
+func newHeaderBuffer(confirmations uint64) *headerBuffer {
+	return &headerBuffer{
+		confirmations: confirmations,
+		headers:       make(map[uint64]*gethTypes.Header),
+	}
+}
+
+func (b *headerBuffer) get(blockNumber uint64) (*gethTypes.Header, bool) {
+	header, ok := b.headers[blockNumber]
+	return header, ok
+}
+
+// add records header and evicts any buffered header old enough that it can
+// no longer be affected by a reorg within the configured confirmation depth.
+func (b *headerBuffer) add(header *gethTypes.Header) {
+	blockNumber := header.Number.Uint64()
+	b.headers[blockNumber] = header
+
+	window := 2 * b.confirmations
+	if blockNumber <= window {
+		return
+	}
+	for n := range b.headers {
+		if n < blockNumber-window {
+			delete(b.headers, n)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package parachaincommitmentrelayer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/snowfork/go-substrate-rpc-client/v2/types"
+
+	"github.com/snowfork/polkadot-ethereum/relayer/chain/relaychain"
+)
+
+// ParachainHeaderProof is a Merkle proof that a parachain head is one of the
+// leaves committed to by the ParachainHeads root carried in a BEEFY MMR leaf.
+// It is returned as a structured value, rather than an opaque string, so it
+// can be ABI-encoded directly as `MessagePackage.paraHeadProof` on the
+// Ethereum side.
+type ParachainHeaderProof struct {
+	LeafIndex     uint64
+	SiblingHashes []types.H256
+	TreeSize      uint64
+}
+
+// createParachainHeaderProof builds a Merkle proof for ourParaHead against the
+// full set of parachain heads committed to by the same MMR leaf. Leaves are
+// the SCALE-encoded (ParaId, HeadData) pairs, sorted by ParaId ascending to
+// match the order in which the relay chain builds the ParachainHeads root,
+// and the tree is hashed with Keccak256 so the proof can be verified by the
+// BeefyLightClient contract.
+func createParachainHeaderProof(allParaHeads []relaychain.ParaHead, ourParaHead relaychain.ParaHead) (ParachainHeaderProof, error) {
+	sorted := make([]relaychain.ParaHead, len(allParaHeads))
+	copy(sorted, allParaHeads)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ParaID < sorted[j].ParaID })
+
+	leaves := make([][]byte, len(sorted))
+	leafIndex := -1
+	for i, head := range sorted {
+		leaf, err := encodeParaHeadLeaf(head)
+		if err != nil {
+			return ParachainHeaderProof{}, err
+		}
+		leaves[i] = leaf
+		if head.ParaID == ourParaHead.ParaID {
+			leafIndex = i
+		}
+	}
+
+	if leafIndex == -1 {
+		return ParachainHeaderProof{}, fmt.Errorf("parachain %d not found in parachain heads set", ourParaHead.ParaID)
+	}
+
+	siblingHashes := merkleSiblingPath(leaves, leafIndex)
+
+	proof := ParachainHeaderProof{
+		LeafIndex:     uint64(leafIndex),
+		SiblingHashes: make([]types.H256, len(siblingHashes)),
+		TreeSize:      uint64(len(leaves)),
+	}
+	for i, sibling := range siblingHashes {
+		proof.SiblingHashes[i] = types.NewH256(sibling)
+	}
+
+	return proof, nil
+}
+
+// encodeParaHeadLeaf SCALE-encodes a (ParaId, HeadData) pair and hashes it
+// with Keccak256 to produce the leaf of the parachain-heads Merkle tree.
+func encodeParaHeadLeaf(head relaychain.ParaHead) ([]byte, error) {
+	encoded, err := types.EncodeToBytes(struct {
+		ParaID   types.U32
+		HeadData types.Bytes
+	}{types.U32(head.ParaID), head.HeadData})
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// merkleSiblingPath returns, for each level of a binary Keccak256 Merkle tree
+// built over leaves, the sibling hash needed to reconstruct the root starting
+// from leaves[index]. A level at which the node is an unpaired trailing node
+// contributes no sibling.
+func merkleSiblingPath(leaves [][]byte, index int) [][]byte {
+	var siblings [][]byte
+	level := leaves
+	for len(level) > 1 {
+		if sibling := index ^ 1; sibling < len(level) {
+			siblings = append(siblings, level[sibling])
+		}
+		level = nextMerkleLevel(level)
+		index /= 2
+	}
+	return siblings
+}
+
+// nextMerkleLevel hashes adjacent pairs of nodes to produce the next level up
+// a binary Merkle tree. An unpaired trailing node is promoted unchanged.
+func nextMerkleLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 < len(level) {
+			pair := append(append([]byte{}, level[i]...), level[i+1]...)
+			next = append(next, crypto.Keccak256(pair))
+		} else {
+			next = append(next, level[i])
+		}
+	}
+	return next
+}
@@ -0,0 +1,172 @@
+package parachaincommitmentrelayer
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/snowfork/go-substrate-rpc-client/v2/types"
+
+	"github.com/snowfork/polkadot-ethereum/relayer/chain/relaychain"
+)
+
+func TestCreateParachainHeaderProofEvenLeafCount(t *testing.T) {
+	heads := []relaychain.ParaHead{
+		{ParaID: 300, HeadData: types.Bytes("chain-c-head")},
+		{ParaID: 100, HeadData: types.Bytes("chain-a-head")},
+		{ParaID: 200, HeadData: types.Bytes("chain-b-head")},
+		{ParaID: 400, HeadData: types.Bytes("chain-d-head")},
+	}
+	ours := heads[2] // ParaID 200
+
+	assertProofReconstructsRoot(t, heads, ours)
+}
+
+func TestCreateParachainHeaderProofOddLeafCount(t *testing.T) {
+	heads := []relaychain.ParaHead{
+		{ParaID: 10, HeadData: types.Bytes("a")},
+		{ParaID: 20, HeadData: types.Bytes("b")},
+		{ParaID: 30, HeadData: types.Bytes("c")},
+	}
+
+	for _, ours := range heads {
+		assertProofReconstructsRoot(t, heads, ours)
+	}
+}
+
+// TestCreateParachainHeaderProofKnownAnswerVector checks createParachainHeaderProof
+// against a root computed from hardcoded, independently-derived SCALE-encoded
+// leaf bytes and Keccak256 hashes, rather than by calling back into
+// encodeParaHeadLeaf/nextMerkleLevel the way referenceParachainHeadsRoot
+// does. This catches a bug that happened to be self-consistent between
+// createParachainHeaderProof and its own helpers but diverged from the
+// relay chain's actual ParachainHeads root construction.
+func TestCreateParachainHeaderProofKnownAnswerVector(t *testing.T) {
+	heads := []relaychain.ParaHead{
+		{ParaID: 100, HeadData: types.Bytes("chain-a-head")},
+		{ParaID: 200, HeadData: types.Bytes("chain-b-head")},
+	}
+
+	// Each leaf is Keccak256 of the SCALE encoding of (ParaId u32 LE,
+	// HeadData as a compact-length-prefixed Vec<u8>): a u32 little-endian
+	// ParaId, followed by a single-byte SCALE compact length (len<<2 for
+	// lengths under 64), followed by the raw head data bytes.
+	leafA := crypto.Keccak256(append([]byte{0x64, 0x00, 0x00, 0x00, 0x30}, []byte("chain-a-head")...))
+	leafB := crypto.Keccak256(append([]byte{0xC8, 0x00, 0x00, 0x00, 0x30}, []byte("chain-b-head")...))
+	wantRoot := types.NewH256(crypto.Keccak256(append(append([]byte{}, leafA...), leafB...)))
+
+	for _, ours := range heads {
+		proof, err := createParachainHeaderProof(heads, ours)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		gotRoot := reconstructRoot(t, ours, proof)
+		if gotRoot != wantRoot {
+			t.Fatalf("paraId %d: reconstructed root %x does not match hardcoded known-answer root %x", ours.ParaID, gotRoot, wantRoot)
+		}
+	}
+}
+
+func TestCreateParachainHeaderProofUnknownParachain(t *testing.T) {
+	heads := []relaychain.ParaHead{
+		{ParaID: 10, HeadData: types.Bytes("a")},
+	}
+	unknown := relaychain.ParaHead{ParaID: 999, HeadData: types.Bytes("z")}
+
+	if _, err := createParachainHeaderProof(heads, unknown); err == nil {
+		t.Fatal("expected an error for a parachain not present in the heads set")
+	}
+}
+
+// assertProofReconstructsRoot builds a proof for ourParaHead and checks that
+// walking its sibling hashes up from ourParaHead's own leaf reconstructs the
+// same root as an independently computed ParachainHeads root, i.e. the root
+// a BeefyLightClient verifying against MMRLeaf.ParachainHeads would expect.
+func assertProofReconstructsRoot(t *testing.T, heads []relaychain.ParaHead, ourParaHead relaychain.ParaHead) {
+	t.Helper()
+
+	proof, err := createParachainHeaderProof(heads, ourParaHead)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantRoot := referenceParachainHeadsRoot(t, heads)
+	gotRoot := reconstructRoot(t, ourParaHead, proof)
+
+	if gotRoot != wantRoot {
+		t.Fatalf("paraId %d: reconstructed root %x does not match expected ParachainHeads root %x", ourParaHead.ParaID, gotRoot, wantRoot)
+	}
+}
+
+// referenceParachainHeadsRoot independently computes the Keccak256 Merkle
+// root over heads sorted by ParaId, mirroring the relay chain's own
+// ParachainHeads root construction, so the test doesn't just re-exercise
+// createParachainHeaderProof's own tree-building code.
+func referenceParachainHeadsRoot(t *testing.T, heads []relaychain.ParaHead) types.H256 {
+	t.Helper()
+
+	sorted := make([]relaychain.ParaHead, len(heads))
+	copy(sorted, heads)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ParaID < sorted[j].ParaID })
+
+	level := make([][]byte, len(sorted))
+	for i, head := range sorted {
+		leaf, err := encodeParaHeadLeaf(head)
+		if err != nil {
+			t.Fatalf("failed to encode leaf: %v", err)
+		}
+		level[i] = leaf
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, crypto.Keccak256(append(append([]byte{}, level[i]...), level[i+1]...)))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		level = next
+	}
+
+	return types.NewH256(level[0])
+}
+
+// reconstructRoot walks proof's sibling hashes up from ourParaHead's own
+// leaf hash to recompute the root the proof claims to attest to, consuming a
+// sibling at a level only when that level actually has one (an unpaired
+// trailing node at a level is promoted without a sibling).
+func reconstructRoot(t *testing.T, ourParaHead relaychain.ParaHead, proof ParachainHeaderProof) types.H256 {
+	t.Helper()
+
+	node, err := encodeParaHeadLeaf(ourParaHead)
+	if err != nil {
+		t.Fatalf("failed to encode leaf: %v", err)
+	}
+
+	index := proof.LeafIndex
+	levelSize := proof.TreeSize
+	siblings := proof.SiblingHashes
+
+	for levelSize > 1 {
+		if index^1 < levelSize {
+			if len(siblings) == 0 {
+				t.Fatalf("ran out of sibling hashes reconstructing the root")
+			}
+			sibling := siblings[0]
+			siblings = siblings[1:]
+
+			if index%2 == 0 {
+				node = crypto.Keccak256(append(append([]byte{}, node...), sibling[:]...))
+			} else {
+				node = crypto.Keccak256(append(append([]byte{}, sibling[:]...), node...))
+			}
+		}
+		index /= 2
+		levelSize = (levelSize + 1) / 2
+	}
+
+	return types.NewH256(node)
+}